@@ -0,0 +1,140 @@
+// Command azblob is a thin CLI over pkg/azureblob: put/get/list/delete/stat
+// against a single container, authenticated through pkg/auth (account shared
+// key by default, to match this command's historical AZURE_ACCOUNT_NAME /
+// AZURE_ACCOUNT_KEY behavior; pass -auth-method for MSI/workload-identity/CLI/SAS).
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/giuliohome-org/go-azure/pkg/auth"
+	"github.com/giuliohome-org/go-azure/pkg/azureblob"
+	"github.com/giuliohome-org/go-azure/pkg/clientopts"
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [flags] <put|get|list|delete|stat> <container> [key] [local-path]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	prefix := flag.String("prefix", "", "prefix to filter on for list")
+	delimiter := flag.String("delimiter", "/", "delimiter to group keys under for list")
+	blockSizeMiB := flag.Int64("block-size-mib", azureblob.DefaultBlockSize/(1024*1024), "upload block size, in MiB")
+	concurrency := flag.Int("concurrency", azureblob.DefaultConcurrency, "number of blocks to move in parallel")
+	authMethod := flag.String("auth-method", string(auth.MethodAccountKey), "credential method: default, workload-identity, managed-identity, client-secret, client-certificate, cli, account-key, sas")
+	azureEnvironment := flag.String("azure-environment", string(auth.AzurePublic), "Azure cloud to authenticate against: AzurePublic, AzureChina, AzureGovernment")
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) < 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	cmd, containerName := args[0], args[1]
+
+	accountName := os.Getenv("AZURE_ACCOUNT_NAME")
+	if accountName == "" {
+		log.Fatal("AZURE_ACCOUNT_NAME must be set.")
+	}
+
+	credential, err := auth.NewCredential(auth.ConfigFromEnv(*authMethod, *azureEnvironment, accountName))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	opts := clientopts.NewClientOptions(clientopts.Config{})
+	backend, err := azureblob.NewBackendFromCredential(accountName, containerName, credential, &opts,
+		azureblob.WithBlockSize(*blockSizeMiB*1024*1024),
+		azureblob.WithConcurrency(*concurrency),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx := context.Background()
+
+	switch cmd {
+	case "put":
+		if len(args) < 4 {
+			log.Fatal("usage: put <container> <key> <local-path>")
+		}
+		key, localPath := args[2], args[3]
+		f, err := os.Open(localPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		stat, err := f.Stat()
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := backend.Put(ctx, key, f, stat.Size()); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("uploaded %s (%d bytes) to %s/%s\n", localPath, stat.Size(), containerName, key)
+
+	case "get":
+		if len(args) < 4 {
+			log.Fatal("usage: get <container> <key> <local-path>")
+		}
+		key, localPath := args[2], args[3]
+		r, err := backend.Get(ctx, key)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer r.Close()
+		f, err := os.Create(localPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		n, err := io.Copy(f, r)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("downloaded %d bytes from %s/%s to %s\n", n, containerName, key, localPath)
+
+	case "list":
+		entries, err := backend.List(ctx, *prefix, *delimiter)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, e := range entries {
+			if e.IsPrefix {
+				fmt.Printf("%s\t<prefix>\n", e.Key)
+				continue
+			}
+			fmt.Printf("%s\t%d\t%s\n", e.Key, e.Size, e.LastModified)
+		}
+
+	case "delete":
+		if len(args) < 3 {
+			log.Fatal("usage: delete <container> <key>")
+		}
+		key := args[2]
+		if err := backend.Delete(ctx, key); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("deleted %s/%s\n", containerName, key)
+
+	case "stat":
+		if len(args) < 3 {
+			log.Fatal("usage: stat <container> <key>")
+		}
+		key := args[2]
+		info, err := backend.Stat(ctx, key)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("%s\t%d bytes\tmodified %s\tetag %s\n", info.Key, info.Size, info.LastModified, info.ETag)
+
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
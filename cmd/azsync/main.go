@@ -0,0 +1,95 @@
+// Command azsync is a thin CLI over pkg/sync: pushes a local directory to
+// a container or pulls a container down to a local directory, authenticated
+// through pkg/auth (account shared key by default, to match this command's
+// historical AZURE_ACCOUNT_NAME / AZURE_ACCOUNT_KEY behavior; pass
+// -auth-method for MSI/workload-identity/CLI/SAS).
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/giuliohome-org/go-azure/pkg/auth"
+	"github.com/giuliohome-org/go-azure/pkg/azureblob"
+	"github.com/giuliohome-org/go-azure/pkg/clientopts"
+	"github.com/giuliohome-org/go-azure/pkg/sync"
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [flags] <push|pull> <container> <local-dir>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	checksum := flag.Bool("checksum", true, "compare by MD5 checksum instead of size-only")
+	sizeOnly := flag.Bool("size-only", false, "compare by size only, skipping checksums")
+	update := flag.Bool("update", false, "transfer only when the source is newer than the destination")
+	del := flag.Bool("delete", false, "remove destination entries with no source counterpart")
+	concurrency := flag.Int("concurrency", 8, "number of files to transfer in parallel")
+	authMethod := flag.String("auth-method", string(auth.MethodAccountKey), "credential method: default, workload-identity, managed-identity, client-secret, client-certificate, cli, account-key, sas")
+	azureEnvironment := flag.String("azure-environment", string(auth.AzurePublic), "Azure cloud to authenticate against: AzurePublic, AzureChina, AzureGovernment")
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) != 3 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	direction, containerName, localDir := args[0], args[1], args[2]
+
+	accountName := os.Getenv("AZURE_ACCOUNT_NAME")
+	if accountName == "" {
+		log.Fatal("AZURE_ACCOUNT_NAME must be set.")
+	}
+
+	credential, err := auth.NewCredential(auth.ConfigFromEnv(*authMethod, *azureEnvironment, accountName))
+	if err != nil {
+		log.Fatal(err)
+	}
+	clientOptions := clientopts.NewClientOptions(clientopts.Config{})
+	backend, err := azureblob.NewBackendFromCredential(accountName, containerName, credential, &clientOptions)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	mode := sync.CompareChecksum
+	switch {
+	case *sizeOnly:
+		mode = sync.CompareSizeOnly
+	case *update:
+		mode = sync.CompareUpdate
+	case !*checksum:
+		mode = sync.CompareSizeOnly
+	}
+
+	syncer := sync.NewSyncer(backend, localDir, sync.Options{
+		Mode:        mode,
+		Concurrency: *concurrency,
+		Delete:      *del,
+		Progress: func(e sync.Event) {
+			if e.Err != nil {
+				fmt.Printf("%s %s: %v\n", e.Action, e.Key, e.Err)
+				return
+			}
+			fmt.Printf("%s %s\n", e.Action, e.Key)
+		},
+	})
+
+	ctx := context.Background()
+	var stats sync.Stats
+	switch direction {
+	case "push":
+		stats, err = syncer.Push(ctx)
+	case "pull":
+		stats, err = syncer.Pull(ctx)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("uploaded=%d downloaded=%d skipped=%d deleted=%d\n", stats.Uploaded, stats.Downloaded, stats.Skipped, stats.Deleted)
+}
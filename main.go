@@ -3,20 +3,28 @@ package main
 import (
 	"context"
 	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"math/rand"
+	"net"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/sas"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/service"
+	"github.com/giuliohome-org/go-azure/pkg/auth"
+	"github.com/giuliohome-org/go-azure/pkg/azureblob"
+	"github.com/giuliohome-org/go-azure/pkg/clientopts"
+	"github.com/giuliohome-org/go-azure/pkg/management"
 )
 
 var (
@@ -31,14 +39,125 @@ var (
 	blobContainersClient *armstorage.BlobContainersClient
 )
 
+// SAS generation mode, selected with -sas-mode.
+const (
+	sasModeSharedKey      = "shared-key"
+	sasModeUserDelegation = "user-delegation"
+)
+
+var (
+	sasMode        = flag.String("sas-mode", sasModeSharedKey, "SAS signing mode: shared-key or user-delegation")
+	sasPermissions = flag.String("sas-permissions", "rl", "permissions to grant on the SAS, e.g. \"rl\" for read+list")
+	sasExpiry      = flag.Duration("sas-expiry", 15*time.Minute, "how long the SAS stays valid")
+	sasIPRange     = flag.String("sas-ip-range", "", "optional IP range allowed to use the SAS, e.g. 168.1.5.60-168.1.5.70")
+	sasHTTPSOnly   = flag.Bool("sas-https-only", true, "restrict the SAS to HTTPS requests")
+
+	authMethod       = flag.String("auth-method", string(auth.MethodAccountKey), "credential method: default, workload-identity, managed-identity, client-secret, client-certificate, cli, account-key, sas")
+	azureEnvironment = flag.String("azure-environment", string(auth.AzurePublic), "Azure cloud to authenticate against: AzurePublic, AzureChina, AzureGovernment")
+
+	enableTracing = flag.Bool("enable-tracing", false, "wire an OpenTelemetry TracingProvider into every client")
+
+	specFile = flag.String("spec", "", "path to a YAML/JSON pkg/management.Spec; when set, reconciles the resource group, storage account and containers it describes instead of running the built-in demo flow")
+)
+
+// sdkClientOptions builds the retry/logging/tracing options shared by every
+// client this module constructs.
+func sdkClientOptions() azcore.ClientOptions {
+	return clientopts.NewClientOptions(clientopts.Config{EnableTracing: *enableTracing})
+}
+
+// tokenCredentialConfig builds an auth.Config from -auth-method/-azure-environment
+// plus the usual AZURE_* environment variables, so every data-plane and
+// control-plane call site in this file (the shared-key demo flow, the
+// armstorage path, genUserDelegationSasToken and reconcileSpec) authenticates
+// the same way.
+func tokenCredentialConfig() auth.Config {
+	return auth.ConfigFromEnv(*authMethod, *azureEnvironment, storageAccountName)
+}
+
+// parseContainerPermissions turns a shorthand permission string (e.g. "rwl")
+// into sas.ContainerPermissions, recognising r(ead) a(dd) c(reate) w(rite)
+// d(elete) l(ist) t(ag).
+func parseContainerPermissions(shorthand string) sas.ContainerPermissions {
+	var perms sas.ContainerPermissions
+	for _, c := range shorthand {
+		switch c {
+		case 'r':
+			perms.Read = true
+		case 'a':
+			perms.Add = true
+		case 'c':
+			perms.Create = true
+		case 'w':
+			perms.Write = true
+		case 'd':
+			perms.Delete = true
+		case 'l':
+			perms.List = true
+		case 't':
+			perms.Tag = true
+		}
+	}
+	return perms
+}
+
+// genUserDelegationSasToken obtains a user-delegation key through Azure AD
+// (no AZURE_ACCOUNT_KEY required) and uses it to sign a container SAS. This
+// is what lets the token be minted from MSI, workload-identity or `az login`
+// sessions, matching the auth options the SDK itself exposes. The actual
+// signing lives in pkg/azureblob.SignContainerUserDelegationSAS so it is a
+// reusable library call, not a main.go-only helper.
+func genUserDelegationSasToken(ctx context.Context) {
+	credential, err := auth.NewCredential(tokenCredentialConfig())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if credential.Token == nil {
+		log.Fatalf("user-delegation SAS requires a token credential, got auth method %q", *authMethod)
+	}
+
+	sasURL, err := azureblob.SignContainerUserDelegationSAS(ctx, storageAccountName, containerName, credential.Token, to.Ptr(sdkClientOptions()), azureblob.UserDelegationSASOptions{
+		Permissions: parseContainerPermissions(*sasPermissions),
+		Expiry:      *sasExpiry,
+		IPRange:     parseSasIPRange(*sasIPRange),
+		HTTPSOnly:   *sasHTTPSOnly,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("SAS URL list files %v&restype=container&comp=list\n", sasURL)
+}
+
+// parseSasIPRange turns "start-end" (or a single IP) into a sas.IPRange,
+// returning the zero value when ipRange is empty so it is omitted from the
+// signed fields.
+func parseSasIPRange(ipRange string) sas.IPRange {
+	if ipRange == "" {
+		return sas.IPRange{}
+	}
+	parts := strings.SplitN(ipRange, "-", 2)
+	if len(parts) == 1 {
+		return sas.IPRange{Start: net.ParseIP(parts[0])}
+	}
+	return sas.IPRange{Start: net.ParseIP(parts[0]), End: net.ParseIP(parts[1])}
+}
+
 func genSaSToken(scred *azblob.SharedKeyCredential) {
-	// Create Blob Signature Values with desired permissions and sign with user delegation credential
+	protocol := sas.ProtocolHTTPSandHTTP
+	if *sasHTTPSOnly {
+		protocol = sas.ProtocolHTTPS
+	}
+
+	// Create Blob Signature Values with desired permissions and sign with the account's shared key
+	startTime := time.Now()
 	sasQueryParams, err := sas.BlobSignatureValues{
-		Protocol:      sas.ProtocolHTTPS,
-		StartTime:     time.Now(),
-		ExpiryTime:    time.Now().Add(15 * time.Minute),
-		Permissions:   to.Ptr(sas.ContainerPermissions{Read: true, List: true}).String(),
+		Protocol:      protocol,
+		StartTime:     startTime,
+		ExpiryTime:    startTime.Add(*sasExpiry),
+		Permissions:   to.Ptr(parseContainerPermissions(*sasPermissions)).String(),
 		ContainerName: containerName,
+		IPRange:       parseSasIPRange(*sasIPRange),
 	}.SignWithSharedKey(scred)
 	if err != nil {
 		log.Fatal(err)
@@ -55,14 +174,14 @@ func printSasToken(accountKey string, scred *azblob.SharedKeyCredential) {
 		log.Fatal(err)
 		return
 	}
-	cli_o := &service.GetSASURLOptions{ StartTime: to.Ptr( time.Now().Add(2 * time.Second) )} 
+	cli_o := &service.GetSASURLOptions{StartTime: to.Ptr(time.Now().Add(2 * time.Second))}
 	sas_url, err := blob_client.ServiceClient().GetSASURL(
-		sas.AccountResourceTypes{ Container: true },
+		sas.AccountResourceTypes{Container: true},
 		sas.AccountPermissions{
 			Create: true, Delete: true, List: true, Add: true,
 		},
-		time.Now().Add(24 * time.Hour),
-		cli_o,	
+		time.Now().Add(24*time.Hour),
+		cli_o,
 	)
 	if err != nil {
 		log.Fatal(err)
@@ -73,52 +192,80 @@ func printSasToken(accountKey string, scred *azblob.SharedKeyCredential) {
 }
 
 func main() {
+	flag.Parse()
 	fmt.Println("Starting azure golang main.")
 
-	accountKey := os.Getenv("AZURE_ACCOUNT_KEY")
-	if len(accountKey) == 0 {
-		log.Fatal("AZURE_ACCOUNT_KEY is not set.")
-	}
-	
 	ctx := context.Background()
-	scred, err := azblob.NewSharedKeyCredential(storageAccountName, accountKey)
+
+	if *sasMode == sasModeUserDelegation {
+		genUserDelegationSasToken(ctx)
+		return
+	}
+
+	if *specFile != "" {
+		reconcileSpec(ctx, *specFile)
+		return
+	}
+
+	// Route the data-plane client through the same credential chain as
+	// genUserDelegationSasToken and the armstorage path below, instead of
+	// hard-coding a shared-key credential: -auth-method (account-key by
+	// default, to match this flow's historical AZURE_ACCOUNT_KEY-only
+	// behavior) picks which of credential.Token/SharedKey/SASURL is set.
+	credential, err := auth.NewCredential(tokenCredentialConfig())
 	if err != nil {
 		log.Fatal(err)
 	}
-	blob_client, err := azblob.NewClientWithSharedKeyCredential(fmt.Sprintf("https://%s.blob.core.windows.net", storageAccountName), scred, nil)
+
+	var blob_client *azblob.Client
+	switch {
+	case credential.SharedKey != nil:
+		blob_client, err = azblob.NewClientWithSharedKeyCredential(fmt.Sprintf("https://%s.blob.core.windows.net", storageAccountName), credential.SharedKey, &azblob.ClientOptions{ClientOptions: sdkClientOptions()})
+	case credential.Token != nil:
+		blob_client, err = azblob.NewClient(fmt.Sprintf("https://%s.blob.core.windows.net", storageAccountName), credential.Token, &azblob.ClientOptions{ClientOptions: sdkClientOptions()})
+	case credential.SASURL != "":
+		blob_client, err = azblob.NewClientWithNoCredential(credential.SASURL, &azblob.ClientOptions{ClientOptions: sdkClientOptions()})
+	default:
+		log.Fatalf("data-plane access requires a token, shared key or SAS URL credential, got auth method %q", *authMethod)
+	}
 	if err != nil {
 		log.Fatal(err)
-		return
 	}
+
 	blob_resp, err := blob_client.CreateContainer(ctx, containerName, nil)
 	var blobErr *azcore.ResponseError
 	if errors.As(err, &blobErr) {
 		if blobErr.ErrorCode == "ContainerAlreadyExists" {
 			log.Println("Blob container already exists")
-			genSaSToken(scred)
-			return
 		} else {
 			log.Fatal(err)
-		return
+			return
 		}
 	} else {
 		log.Println("Created blob container vers " + *blob_resp.Version)
-		genSaSToken(scred)
-		return
 	}
 
+	// Shared-key signing only works when account-key auth actually produced
+	// a SharedKeyCredential; other methods should use -sas-mode=user-delegation.
+	if credential.SharedKey != nil {
+		genSaSToken(credential.SharedKey)
+	}
+	return
+
 	// skipping the rest of main ( with armstorage and azidentity )
 	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
 	if len(subscriptionID) == 0 {
 		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
 	}
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	credential, err = auth.NewCredential(tokenCredentialConfig())
 	if err != nil {
-		// handle error
 		log.Fatal(err)
 	}
+	if credential.Token == nil {
+		log.Fatalf("storage account management requires a token credential, got auth method %q", *authMethod)
+	}
 
-	storageClientFactory, err = armstorage.NewClientFactory(subscriptionID, cred, nil)
+	storageClientFactory, err = armstorage.NewClientFactory(subscriptionID, credential.Token, &arm.ClientOptions{ClientOptions: sdkClientOptions()})
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -209,6 +356,69 @@ func createBlobContainers(ctx context.Context) (*armstorage.BlobContainer, error
 	return &blobContainerResp.BlobContainer, nil
 }
 
+// reconcileSpec loads a management.Spec from specPath and ensures the
+// resource group, storage account and containers it describes exist,
+// logging any drift found on resources that already existed.
+func reconcileSpec(ctx context.Context, specPath string) {
+	spec, err := management.LoadSpec(specPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
+	if len(subscriptionID) == 0 {
+		log.Fatal("AZURE_SUBSCRIPTION_ID is not set.")
+	}
+
+	credential, err := auth.NewCredential(tokenCredentialConfig())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if credential.Token == nil {
+		log.Fatalf("spec reconciliation requires a token credential, got auth method %q", *authMethod)
+	}
+
+	armOpts := &arm.ClientOptions{ClientOptions: sdkClientOptions()}
+	resourceGroupsClient, err := armresources.NewResourceGroupsClient(subscriptionID, credential.Token, armOpts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	factory, err := armstorage.NewClientFactory(subscriptionID, credential.Token, armOpts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	mgmt := management.NewClient(resourceGroupsClient, factory)
+
+	if _, err := mgmt.EnsureResourceGroup(ctx, spec.ResourceGroup); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("resource group %q ensured", spec.ResourceGroup.Name)
+
+	_, drift, err := mgmt.EnsureStorageAccount(ctx, spec.ResourceGroup.Name, spec.StorageAccount)
+	if err != nil {
+		log.Fatal(err)
+	}
+	logDrift("storage account "+spec.StorageAccount.Name, drift)
+
+	for _, containerSpec := range spec.Containers {
+		_, drift, err := mgmt.EnsureContainer(ctx, spec.ResourceGroup.Name, spec.StorageAccount.Name, containerSpec)
+		if err != nil {
+			log.Fatal(err)
+		}
+		logDrift("container "+containerSpec.Name, drift)
+	}
+}
+
+func logDrift(resource string, drift []management.DriftField) {
+	if len(drift) == 0 {
+		log.Printf("%s matches spec", resource)
+		return
+	}
+	for _, d := range drift {
+		log.Printf("%s: %s drifted, desired=%q observed=%q", resource, d.Field, d.Desired, d.Observed)
+	}
+}
+
 func getBlobContainer(ctx context.Context) (blobContainer *armstorage.BlobContainer, err error) {
 
 	blobContainerResp, err := blobContainersClient.Get(ctx, resourceGroupName, storageAccountName, containerName, nil)
@@ -0,0 +1,209 @@
+// Package auth builds Azure credentials from a declarative Config instead
+// of every caller hard-coding a single azidentity constructor. It is used
+// by both the control-plane (armstorage) and data-plane (azblob) paths so
+// the same binary can authenticate with MSI, workload identity, a service
+// principal, the Azure CLI, an account key or a pre-signed SAS/connection
+// string, and can target a sovereign cloud via Environment.
+package auth
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+)
+
+// Method selects which credential constructor Config should use.
+type Method string
+
+const (
+	MethodDefault           Method = "default"            // azidentity.NewDefaultAzureCredential
+	MethodWorkloadIdentity  Method = "workload-identity"  // azidentity.NewWorkloadIdentityCredential
+	MethodManagedIdentity   Method = "managed-identity"   // azidentity.NewManagedIdentityCredential
+	MethodClientSecret      Method = "client-secret"      // azidentity.NewClientSecretCredential
+	MethodClientCertificate Method = "client-certificate" // azidentity.NewClientCertificateCredential
+	MethodCLI               Method = "cli"                // azidentity.NewAzureCLICredential
+	MethodAccountKey        Method = "account-key"        // azblob.NewSharedKeyCredential
+	MethodSAS               Method = "sas"                // a pre-signed SAS URL or connection string
+)
+
+// Environment selects which Azure cloud to authenticate against. MethodCLI
+// does not use it: the Azure CLI credential authenticates against whatever
+// cloud the az CLI itself is configured for.
+type Environment string
+
+const (
+	AzurePublic     Environment = "AzurePublic"
+	AzureChina      Environment = "AzureChina"
+	AzureGovernment Environment = "AzureGovernment"
+)
+
+// Config declares how to obtain a credential. Only the fields relevant to
+// Method need to be set; the rest are ignored.
+type Config struct {
+	Method      Method
+	Environment Environment // defaults to AzurePublic
+
+	// MethodClientSecret / MethodClientCertificate / MethodWorkloadIdentity
+	TenantID string
+	ClientID string
+
+	// MethodClientSecret
+	ClientSecret string
+
+	// MethodClientCertificate
+	CertificatePath     string
+	CertificatePassword string
+
+	// MethodManagedIdentity; ClientID above is used as the user-assigned
+	// identity's client ID when set, otherwise the system-assigned identity
+	// is used.
+
+	// MethodAccountKey
+	AccountName string
+	AccountKey  string
+
+	// MethodSAS
+	SASURL string
+}
+
+// ConfigFromEnv builds a Config for method/environment out of the AZURE_*
+// environment variables every CLI in this module reads the same way,
+// falling back to defaultAccountName when AZURE_ACCOUNT_NAME is unset so a
+// caller that already knows its storage account doesn't have to duplicate
+// it in the environment just to use MethodAccountKey.
+func ConfigFromEnv(method, environment, defaultAccountName string) Config {
+	accountName := os.Getenv("AZURE_ACCOUNT_NAME")
+	if accountName == "" {
+		accountName = defaultAccountName
+	}
+	return Config{
+		Method:              Method(method),
+		Environment:         Environment(environment),
+		TenantID:            os.Getenv("AZURE_TENANT_ID"),
+		ClientID:            os.Getenv("AZURE_CLIENT_ID"),
+		ClientSecret:        os.Getenv("AZURE_CLIENT_SECRET"),
+		CertificatePath:     os.Getenv("AZURE_CLIENT_CERTIFICATE_PATH"),
+		CertificatePassword: os.Getenv("AZURE_CLIENT_CERTIFICATE_PASSWORD"),
+		AccountName:         accountName,
+		AccountKey:          os.Getenv("AZURE_ACCOUNT_KEY"),
+		SASURL:              os.Getenv("AZURE_SAS_URL"),
+	}
+}
+
+// Credential is the outcome of resolving a Config: exactly one of Token,
+// SharedKey or SASURL is set, matching how the armstorage and azblob
+// clients each want to be authenticated.
+type Credential struct {
+	Token     azcore.TokenCredential
+	SharedKey *azblob.SharedKeyCredential
+	SASURL    string
+}
+
+// NewCredential resolves cfg into a Credential.
+func NewCredential(cfg Config) (*Credential, error) {
+	cloudCfg := cloudConfiguration(cfg.Environment)
+
+	switch cfg.Method {
+	case "", MethodDefault:
+		cred, err := azidentity.NewDefaultAzureCredential(&azidentity.DefaultAzureCredentialOptions{
+			ClientOptions: azcore.ClientOptions{Cloud: cloudCfg},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("auth: default credential: %w", err)
+		}
+		return &Credential{Token: cred}, nil
+
+	case MethodWorkloadIdentity:
+		cred, err := azidentity.NewWorkloadIdentityCredential(&azidentity.WorkloadIdentityCredentialOptions{
+			ClientOptions: azcore.ClientOptions{Cloud: cloudCfg},
+			TenantID:      cfg.TenantID,
+			ClientID:      cfg.ClientID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("auth: workload identity credential: %w", err)
+		}
+		return &Credential{Token: cred}, nil
+
+	case MethodManagedIdentity:
+		opts := &azidentity.ManagedIdentityCredentialOptions{
+			ClientOptions: azcore.ClientOptions{Cloud: cloudCfg},
+		}
+		if cfg.ClientID != "" {
+			opts.ID = azidentity.ClientID(cfg.ClientID)
+		}
+		cred, err := azidentity.NewManagedIdentityCredential(opts)
+		if err != nil {
+			return nil, fmt.Errorf("auth: managed identity credential: %w", err)
+		}
+		return &Credential{Token: cred}, nil
+
+	case MethodClientSecret:
+		cred, err := azidentity.NewClientSecretCredential(cfg.TenantID, cfg.ClientID, cfg.ClientSecret, &azidentity.ClientSecretCredentialOptions{
+			ClientOptions: azcore.ClientOptions{Cloud: cloudCfg},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("auth: client secret credential: %w", err)
+		}
+		return &Credential{Token: cred}, nil
+
+	case MethodClientCertificate:
+		certData, err := os.ReadFile(cfg.CertificatePath)
+		if err != nil {
+			return nil, fmt.Errorf("auth: reading certificate %q: %w", cfg.CertificatePath, err)
+		}
+		certs, key, err := azidentity.ParseCertificates(certData, []byte(cfg.CertificatePassword))
+		if err != nil {
+			return nil, fmt.Errorf("auth: parsing certificate: %w", err)
+		}
+		cred, err := azidentity.NewClientCertificateCredential(cfg.TenantID, cfg.ClientID, certs, key, &azidentity.ClientCertificateCredentialOptions{
+			ClientOptions: azcore.ClientOptions{Cloud: cloudCfg},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("auth: client certificate credential: %w", err)
+		}
+		return &Credential{Token: cred}, nil
+
+	case MethodCLI:
+		cred, err := azidentity.NewAzureCLICredential(nil)
+		if err != nil {
+			return nil, fmt.Errorf("auth: azure cli credential: %w", err)
+		}
+		return &Credential{Token: cred}, nil
+
+	case MethodAccountKey:
+		if cfg.AccountName == "" || cfg.AccountKey == "" {
+			return nil, fmt.Errorf("auth: AccountName and AccountKey must both be set for method %q", MethodAccountKey)
+		}
+		cred, err := azblob.NewSharedKeyCredential(cfg.AccountName, cfg.AccountKey)
+		if err != nil {
+			return nil, fmt.Errorf("auth: shared key credential: %w", err)
+		}
+		return &Credential{SharedKey: cred}, nil
+
+	case MethodSAS:
+		if cfg.SASURL == "" {
+			return nil, fmt.Errorf("auth: SASURL must be set for method %q", MethodSAS)
+		}
+		return &Credential{SASURL: cfg.SASURL}, nil
+
+	default:
+		return nil, fmt.Errorf("auth: unknown method %q", cfg.Method)
+	}
+}
+
+// cloudConfiguration maps Environment to the azcore cloud configuration the
+// SDK should route requests through.
+func cloudConfiguration(env Environment) cloud.Configuration {
+	switch env {
+	case AzureChina:
+		return cloud.AzureChina
+	case AzureGovernment:
+		return cloud.AzureGovernment
+	default:
+		return cloud.AzurePublic
+	}
+}
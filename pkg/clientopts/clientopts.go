@@ -0,0 +1,77 @@
+// Package clientopts builds the azcore.ClientOptions shared by every client
+// this module constructs (armstorage.NewClientFactory, azblob's client
+// constructors, ...): retry/backoff tuning and a logging policy that tags
+// each request with a correlation ID and redacts SAS query parameters
+// before anything is logged.
+package clientopts
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/tracing/azotel"
+	"go.opentelemetry.io/otel"
+)
+
+// Config tunes the retry and observability behaviour applied to every
+// client. The zero value is a sane, moderately patient default.
+type Config struct {
+	MaxRetries    int32         // default 3
+	RetryDelay    time.Duration // default 4s
+	MaxRetryDelay time.Duration // default 60s
+	TryTimeout    time.Duration // default 0 (no per-try timeout)
+
+	// EnableTracing wires an OpenTelemetry TracingProvider (via azotel) into
+	// the client, using otel.GetTracerProvider() as the provider.
+	EnableTracing bool
+}
+
+// retriableStatusCodes is the same set the SDK retries by default, 429
+// included, spelled out so the retried codes are explicit here: this module
+// talks to the storage data plane, where throttling (429 with Retry-After)
+// is routine.
+var retriableStatusCodes = []int{
+	http.StatusRequestTimeout,
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// NewClientOptions builds the azcore.ClientOptions that should be embedded
+// in every *.ClientOptions this module passes to an Azure SDK constructor.
+func NewClientOptions(cfg Config) azcore.ClientOptions {
+	opts := azcore.ClientOptions{
+		Retry: policy.RetryOptions{
+			MaxRetries:    coalesce(cfg.MaxRetries, 3),
+			RetryDelay:    coalesceDuration(cfg.RetryDelay, 4*time.Second),
+			MaxRetryDelay: coalesceDuration(cfg.MaxRetryDelay, 60*time.Second),
+			TryTimeout:    cfg.TryTimeout,
+			StatusCodes:   retriableStatusCodes,
+		},
+		PerCallPolicies: []policy.Policy{newLoggingPolicy()},
+	}
+
+	if cfg.EnableTracing {
+		opts.TracingProvider = azotel.NewTracingProvider(otel.GetTracerProvider(), nil)
+	}
+
+	return opts
+}
+
+func coalesce(v, def int32) int32 {
+	if v == 0 {
+		return def
+	}
+	return v
+}
+
+func coalesceDuration(v, def time.Duration) time.Duration {
+	if v == 0 {
+		return def
+	}
+	return v
+}
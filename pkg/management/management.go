@@ -0,0 +1,211 @@
+// Package management offers an idempotent, spec-driven API over the
+// armstorage control plane: EnsureResourceGroup, EnsureStorageAccount and
+// EnsureContainer each reconcile a desired Spec against the observed Azure
+// resource (creating it if missing, reporting which fields drifted if it
+// already exists) instead of the old linear "create, bail on
+// ContainerAlreadyExists" flow.
+package management
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
+)
+
+// DriftField records a single property that differs between a Spec and the
+// Azure resource actually observed.
+type DriftField struct {
+	Field    string
+	Desired  string
+	Observed string
+}
+
+// Client reconciles resource groups, storage accounts and containers
+// against a desired Spec, the way fluxcd/source-controller reconciles its
+// storage backends: fetch observed state and diff it against desired state.
+// EnsureContainer only calls Update when something drifted, and
+// EnsureStorageAccount never modifies an existing account;
+// EnsureResourceGroup calls CreateOrUpdate unconditionally.
+type Client struct {
+	resourceGroups *armresources.ResourceGroupsClient
+	accounts       *armstorage.AccountsClient
+	blobServices   *armstorage.BlobServicesClient
+	containers     *armstorage.BlobContainersClient
+}
+
+// NewClient builds a management Client from a resource-groups client and an
+// armstorage factory sharing the same credential and pkg/clientopts options.
+func NewClient(resourceGroups *armresources.ResourceGroupsClient, storageFactory *armstorage.ClientFactory) *Client {
+	return &Client{
+		resourceGroups: resourceGroups,
+		accounts:       storageFactory.NewAccountsClient(),
+		blobServices:   storageFactory.NewBlobServicesClient(),
+		containers:     storageFactory.NewBlobContainersClient(),
+	}
+}
+
+// EnsureResourceGroup creates spec's resource group if missing, or updates
+// its location if it already exists. CreateOrUpdate is idempotent on its
+// own, so this is a thin wrapper kept for symmetry with the other Ensure*
+// calls.
+func (c *Client) EnsureResourceGroup(ctx context.Context, spec ResourceGroupSpec) (armresources.ResourceGroup, error) {
+	resp, err := c.resourceGroups.CreateOrUpdate(ctx, spec.Name, armresources.ResourceGroup{
+		Location: to.Ptr(spec.Location),
+	}, nil)
+	if err != nil {
+		return armresources.ResourceGroup{}, fmt.Errorf("management: ensuring resource group %q: %w", spec.Name, err)
+	}
+	return resp.ResourceGroup, nil
+}
+
+// EnsureStorageAccount creates the storage account described by spec if it
+// does not exist yet, including its account-wide blob versioning/soft-delete
+// settings. If it already exists, EnsureStorageAccount leaves it untouched
+// and reports which fields drifted from spec, since most account
+// properties (SKU, kind, HNS) cannot be changed after creation. Drift is
+// reported for SKU, kind, minimum TLS version and HNS only; the versioning
+// and soft-delete settings are applied at creation and not compared later.
+func (c *Client) EnsureStorageAccount(ctx context.Context, resourceGroupName string, spec StorageAccountSpec) (armstorage.Account, []DriftField, error) {
+	existing, err := c.accounts.GetProperties(ctx, resourceGroupName, spec.Name, nil)
+	if isNotFound(err) {
+		return c.createStorageAccount(ctx, resourceGroupName, spec)
+	}
+	if err != nil {
+		return armstorage.Account{}, nil, fmt.Errorf("management: getting storage account %q: %w", spec.Name, err)
+	}
+	return existing.Account, diffStorageAccount(spec, existing.Account), nil
+}
+
+func (c *Client) createStorageAccount(ctx context.Context, resourceGroupName string, spec StorageAccountSpec) (armstorage.Account, []DriftField, error) {
+	poller, err := c.accounts.BeginCreate(ctx, resourceGroupName, spec.Name, armstorage.AccountCreateParameters{
+		Location: to.Ptr(spec.Location),
+		Kind:     to.Ptr(armstorage.Kind(spec.Kind)),
+		SKU:      &armstorage.SKU{Name: to.Ptr(armstorage.SKUName(spec.SKU))},
+		Properties: &armstorage.AccountPropertiesCreateParameters{
+			MinimumTLSVersion: to.Ptr(armstorage.MinimumTLSVersion(spec.MinimumTLSVersion)),
+			IsHnsEnabled:      to.Ptr(spec.EnableHierarchicalNamespace),
+		},
+	}, nil)
+	if err != nil {
+		return armstorage.Account{}, nil, fmt.Errorf("management: creating storage account %q: %w", spec.Name, err)
+	}
+
+	resp, err := poller.PollUntilDone(ctx, nil)
+	if err != nil {
+		return armstorage.Account{}, nil, fmt.Errorf("management: waiting for storage account %q: %w", spec.Name, err)
+	}
+
+	if _, err := c.blobServices.SetServiceProperties(ctx, resourceGroupName, spec.Name, armstorage.BlobServiceProperties{
+		BlobServiceProperties: &armstorage.BlobServicePropertiesProperties{
+			IsVersioningEnabled: to.Ptr(spec.EnableVersioning),
+			ContainerDeleteRetentionPolicy: &armstorage.DeleteRetentionPolicy{
+				Enabled: to.Ptr(spec.SoftDeleteRetentionDays > 0),
+				Days:    to.Ptr(spec.SoftDeleteRetentionDays),
+			},
+		},
+	}, nil); err != nil {
+		return resp.Account, nil, fmt.Errorf("management: setting blob service properties for %q: %w", spec.Name, err)
+	}
+
+	return resp.Account, nil, nil
+}
+
+func diffStorageAccount(spec StorageAccountSpec, observed armstorage.Account) []DriftField {
+	var drift []DriftField
+	if observed.SKU != nil && observed.SKU.Name != nil && string(*observed.SKU.Name) != spec.SKU {
+		drift = append(drift, DriftField{Field: "sku", Desired: spec.SKU, Observed: string(*observed.SKU.Name)})
+	}
+	if observed.Kind != nil && string(*observed.Kind) != spec.Kind {
+		drift = append(drift, DriftField{Field: "kind", Desired: spec.Kind, Observed: string(*observed.Kind)})
+	}
+	if props := observed.Properties; props != nil {
+		if props.MinimumTLSVersion != nil && string(*props.MinimumTLSVersion) != spec.MinimumTLSVersion {
+			drift = append(drift, DriftField{Field: "minimumTLSVersion", Desired: spec.MinimumTLSVersion, Observed: string(*props.MinimumTLSVersion)})
+		}
+		if props.IsHnsEnabled != nil && *props.IsHnsEnabled != spec.EnableHierarchicalNamespace {
+			drift = append(drift, DriftField{Field: "enableHierarchicalNamespace", Desired: fmt.Sprint(spec.EnableHierarchicalNamespace), Observed: fmt.Sprint(*props.IsHnsEnabled)})
+		}
+	}
+	return drift
+}
+
+// EnsureContainer creates the container described by spec if it does not
+// exist, or patches its mutable properties (currently PublicAccess) when
+// they drifted.
+func (c *Client) EnsureContainer(ctx context.Context, resourceGroupName, accountName string, spec ContainerSpec) (armstorage.BlobContainer, []DriftField, error) {
+	existing, err := c.containers.Get(ctx, resourceGroupName, accountName, spec.Name, nil)
+	if isNotFound(err) {
+		created, err := c.containers.Create(ctx, resourceGroupName, accountName, spec.Name, containerProperties(spec), nil)
+		if err != nil {
+			return armstorage.BlobContainer{}, nil, fmt.Errorf("management: creating container %q: %w", spec.Name, err)
+		}
+		return created.BlobContainer, nil, nil
+	}
+	if err != nil {
+		return armstorage.BlobContainer{}, nil, fmt.Errorf("management: getting container %q: %w", spec.Name, err)
+	}
+
+	drift := diffContainer(spec, existing.BlobContainer)
+	if len(drift) == 0 {
+		return existing.BlobContainer, nil, nil
+	}
+
+	updated, err := c.containers.Update(ctx, resourceGroupName, accountName, spec.Name, containerProperties(spec), nil)
+	if err != nil {
+		return armstorage.BlobContainer{}, drift, fmt.Errorf("management: updating container %q: %w", spec.Name, err)
+	}
+	return updated.BlobContainer, drift, nil
+}
+
+func containerProperties(spec ContainerSpec) armstorage.BlobContainer {
+	return armstorage.BlobContainer{
+		ContainerProperties: &armstorage.ContainerProperties{
+			PublicAccess: to.Ptr(armstorage.PublicAccess(spec.PublicAccess)),
+			ImmutableStorageWithVersioning: &armstorage.ImmutableStorageWithVersioning{
+				Enabled: to.Ptr(spec.ImmutableStorageEnabled),
+			},
+		},
+	}
+}
+
+func diffContainer(spec ContainerSpec, observed armstorage.BlobContainer) []DriftField {
+	var drift []DriftField
+	props := observed.ContainerProperties
+	if props == nil {
+		return drift
+	}
+	if props.PublicAccess != nil && string(*props.PublicAccess) != spec.PublicAccess {
+		drift = append(drift, DriftField{Field: "publicAccess", Desired: spec.PublicAccess, Observed: string(*props.PublicAccess)})
+	}
+	if isw := props.ImmutableStorageWithVersioning; isw != nil && isw.Enabled != nil && *isw.Enabled != spec.ImmutableStorageEnabled {
+		drift = append(drift, DriftField{Field: "immutableStorageEnabled", Desired: fmt.Sprint(spec.ImmutableStorageEnabled), Observed: fmt.Sprint(*isw.Enabled)})
+	}
+	return drift
+}
+
+// ListContainers pages through every container in accountName.
+func (c *Client) ListContainers(ctx context.Context, resourceGroupName, accountName string) ([]armstorage.ListContainerItem, error) {
+	var containers []armstorage.ListContainerItem
+	pager := c.containers.NewListPager(resourceGroupName, accountName, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("management: listing containers in %q: %w", accountName, err)
+		}
+		for _, item := range page.Value {
+			containers = append(containers, *item)
+		}
+	}
+	return containers, nil
+}
+
+func isNotFound(err error) bool {
+	var respErr *azcore.ResponseError
+	return errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound
+}
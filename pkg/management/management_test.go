@@ -0,0 +1,136 @@
+package management
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
+)
+
+func TestDiffStorageAccount(t *testing.T) {
+	spec := StorageAccountSpec{
+		SKU:                         "Standard_LRS",
+		Kind:                        "StorageV2",
+		MinimumTLSVersion:           "TLS1_2",
+		EnableHierarchicalNamespace: false,
+	}
+
+	tests := []struct {
+		name     string
+		observed armstorage.Account
+		want     []DriftField
+	}{
+		{
+			name: "matches spec",
+			observed: armstorage.Account{
+				SKU:  &armstorage.SKU{Name: to.Ptr(armstorage.SKUNameStandardLRS)},
+				Kind: to.Ptr(armstorage.KindStorageV2),
+				Properties: &armstorage.AccountProperties{
+					MinimumTLSVersion: to.Ptr(armstorage.MinimumTLSVersionTLS12),
+					IsHnsEnabled:      to.Ptr(false),
+				},
+			},
+			want: nil,
+		},
+		{
+			name: "sku and kind drifted",
+			observed: armstorage.Account{
+				SKU:  &armstorage.SKU{Name: to.Ptr(armstorage.SKUNameStandardGRS)},
+				Kind: to.Ptr(armstorage.KindBlobStorage),
+			},
+			want: []DriftField{
+				{Field: "sku", Desired: "Standard_LRS", Observed: "Standard_GRS"},
+				{Field: "kind", Desired: "StorageV2", Observed: "BlobStorage"},
+			},
+		},
+		{
+			name: "hns drifted",
+			observed: armstorage.Account{
+				Properties: &armstorage.AccountProperties{
+					MinimumTLSVersion: to.Ptr(armstorage.MinimumTLSVersionTLS12),
+					IsHnsEnabled:      to.Ptr(true),
+				},
+			},
+			want: []DriftField{
+				{Field: "enableHierarchicalNamespace", Desired: "false", Observed: "true"},
+			},
+		},
+		{
+			name:     "nil properties reports no drift for account-level fields",
+			observed: armstorage.Account{},
+			want:     nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := diffStorageAccount(spec, tt.observed)
+			assertDriftEqual(t, got, tt.want)
+		})
+	}
+}
+
+func TestDiffContainer(t *testing.T) {
+	spec := ContainerSpec{
+		PublicAccess:            "None",
+		ImmutableStorageEnabled: false,
+	}
+
+	tests := []struct {
+		name     string
+		observed armstorage.BlobContainer
+		want     []DriftField
+	}{
+		{
+			name: "matches spec",
+			observed: armstorage.BlobContainer{
+				ContainerProperties: &armstorage.ContainerProperties{
+					PublicAccess: to.Ptr(armstorage.PublicAccessNone),
+					ImmutableStorageWithVersioning: &armstorage.ImmutableStorageWithVersioning{
+						Enabled: to.Ptr(false),
+					},
+				},
+			},
+			want: nil,
+		},
+		{
+			name: "public access and immutability drifted",
+			observed: armstorage.BlobContainer{
+				ContainerProperties: &armstorage.ContainerProperties{
+					PublicAccess: to.Ptr(armstorage.PublicAccessContainer),
+					ImmutableStorageWithVersioning: &armstorage.ImmutableStorageWithVersioning{
+						Enabled: to.Ptr(true),
+					},
+				},
+			},
+			want: []DriftField{
+				{Field: "publicAccess", Desired: "None", Observed: "Container"},
+				{Field: "immutableStorageEnabled", Desired: "false", Observed: "true"},
+			},
+		},
+		{
+			name:     "nil properties reports no drift",
+			observed: armstorage.BlobContainer{},
+			want:     nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := diffContainer(spec, tt.observed)
+			assertDriftEqual(t, got, tt.want)
+		})
+	}
+}
+
+func assertDriftEqual(t *testing.T, got, want []DriftField) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("drift = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("drift[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
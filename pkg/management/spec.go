@@ -0,0 +1,72 @@
+package management
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Spec is the desired state for a resource group, its storage account and
+// that account's containers, as loaded from a YAML or JSON file by
+// LoadSpec.
+type Spec struct {
+	ResourceGroup  ResourceGroupSpec  `json:"resourceGroup" yaml:"resourceGroup"`
+	StorageAccount StorageAccountSpec `json:"storageAccount" yaml:"storageAccount"`
+	Containers     []ContainerSpec    `json:"containers" yaml:"containers"`
+}
+
+// ResourceGroupSpec is the desired state of a resource group.
+type ResourceGroupSpec struct {
+	Name     string `json:"name" yaml:"name"`
+	Location string `json:"location" yaml:"location"`
+}
+
+// StorageAccountSpec is the desired state of a storage account, including
+// the account-wide blob service settings (versioning, container soft
+// delete) that EnsureStorageAccount applies when it creates the account.
+// Drift in those two settings on an existing account is not reported.
+type StorageAccountSpec struct {
+	Name                        string `json:"name" yaml:"name"`
+	Location                    string `json:"location" yaml:"location"`
+	SKU                         string `json:"sku" yaml:"sku"`   // e.g. "Standard_LRS", "Standard_GRS"
+	Kind                        string `json:"kind" yaml:"kind"` // e.g. "StorageV2"
+	MinimumTLSVersion           string `json:"minimumTLSVersion" yaml:"minimumTLSVersion"`
+	EnableHierarchicalNamespace bool   `json:"enableHierarchicalNamespace" yaml:"enableHierarchicalNamespace"`
+	EnableVersioning            bool   `json:"enableVersioning" yaml:"enableVersioning"`
+	SoftDeleteRetentionDays     int32  `json:"softDeleteRetentionDays" yaml:"softDeleteRetentionDays"`
+}
+
+// ContainerSpec is the desired state of a single blob container.
+type ContainerSpec struct {
+	Name                    string `json:"name" yaml:"name"`
+	PublicAccess            string `json:"publicAccess" yaml:"publicAccess"` // "None", "Blob" or "Container"
+	ImmutableStorageEnabled bool   `json:"immutableStorageEnabled" yaml:"immutableStorageEnabled"`
+}
+
+// LoadSpec reads a Spec from path, choosing YAML or JSON based on its
+// extension (.yaml/.yml vs .json).
+func LoadSpec(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("management: reading spec %q: %w", path, err)
+	}
+
+	var spec Spec
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &spec); err != nil {
+			return nil, fmt.Errorf("management: parsing YAML spec %q: %w", path, err)
+		}
+	case ".json":
+		if err := json.Unmarshal(data, &spec); err != nil {
+			return nil, fmt.Errorf("management: parsing JSON spec %q: %w", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("management: unsupported spec extension %q (want .yaml, .yml or .json)", ext)
+	}
+	return &spec, nil
+}
@@ -0,0 +1,374 @@
+// Package sync implements an rclone-style two-way sync between a local
+// directory tree and an Azure container: list both sides, diff by the
+// configured CompareMode, and transfer only what actually changed, with
+// concurrent workers and a progress callback.
+package sync
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/giuliohome-org/go-azure/pkg/azureblob"
+)
+
+// CompareMode selects how a Syncer decides whether a file needs transferring.
+type CompareMode int
+
+const (
+	CompareChecksum CompareMode = iota // default: compare size and MD5 (a blob with no stored MD5 is always transferred)
+	CompareSizeOnly
+	CompareUpdate // transfer only when the source is newer than the destination
+)
+
+// Options configures a Syncer.
+type Options struct {
+	Mode        CompareMode
+	Concurrency int  // default 8
+	Delete      bool // remove destination entries with no source counterpart
+	Progress    func(Event)
+}
+
+// Event is reported through Options.Progress as each key is processed.
+type Event struct {
+	Key    string
+	Action string // "upload", "download", "move", "skip", "delete"
+	Err    error
+}
+
+// Stats summarizes what a Push or Pull call did.
+type Stats struct {
+	Uploaded   int
+	Downloaded int
+	Skipped    int
+	Deleted    int
+}
+
+// Syncer syncs the directory tree under its local root against a single container.
+type Syncer struct {
+	backend *azureblob.Backend
+	root    string
+	opts    Options
+}
+
+// NewSyncer builds a Syncer rooted at localRoot against backend.
+func NewSyncer(backend *azureblob.Backend, localRoot string, opts Options) *Syncer {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 8
+	}
+	return &Syncer{backend: backend, root: localRoot, opts: opts}
+}
+
+type localFile struct {
+	key     string
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// Push uploads every local file that differs from its matching blob, and
+// (with Options.Delete) removes blobs that have no local counterpart. A
+// local file that needs transferring and whose checksum matches a blob with
+// no local counterpart is copied from that blob with a server-side Copy
+// instead of being re-uploaded; with Options.Delete the source blob is then
+// removed like any other, so the pair amounts to a rename.
+func (s *Syncer) Push(ctx context.Context) (Stats, error) {
+	locals, err := s.listLocal()
+	if err != nil {
+		return Stats{}, err
+	}
+	remotes, err := s.listRemote(ctx)
+	if err != nil {
+		return Stats{}, err
+	}
+
+	localKeys := make(map[string]bool, len(locals))
+	for _, lf := range locals {
+		localKeys[lf.key] = true
+	}
+	moveSources := renameCandidates(remotes, localKeys)
+
+	var stats Stats
+	var mu sync.Mutex
+
+	err = s.forEach(ctx, len(locals), func(ctx context.Context, i int) error {
+		lf := locals[i]
+		if remote, ok := remotes[lf.key]; ok && !s.needsTransfer(lf, remote, true) {
+			s.report(lf.key, "skip", nil)
+			mu.Lock()
+			stats.Skipped++
+			mu.Unlock()
+			return nil
+		}
+
+		if srcKey, ok := s.matchRename(lf, moveSources); ok {
+			err := s.backend.Copy(ctx, srcKey, lf.key)
+			s.report(lf.key, "move", err)
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			stats.Uploaded++
+			mu.Unlock()
+			return nil
+		}
+
+		err := s.backend.PutFile(ctx, lf.key, lf.path)
+		s.report(lf.key, "upload", err)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		stats.Uploaded++
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return stats, err
+	}
+
+	if s.opts.Delete {
+		for key := range remotes {
+			if localKeys[key] {
+				continue
+			}
+			if err := s.backend.Delete(ctx, key); err != nil {
+				return stats, err
+			}
+			s.report(key, "delete", nil)
+			stats.Deleted++
+		}
+	}
+	return stats, nil
+}
+
+// renameCandidates returns the remote keys that have no local counterpart
+// (and so would be deleted by a Delete-mode Push), keyed by their content
+// MD5, since those are the only blobs a local rename could cheaply reuse.
+func renameCandidates(remotes map[string]azureblob.BlobInfo, localKeys map[string]bool) map[string]string {
+	candidates := make(map[string]string)
+	for key, info := range remotes {
+		if localKeys[key] || info.ContentMD5 == nil {
+			continue
+		}
+		candidates[string(info.ContentMD5)] = key
+	}
+	return candidates
+}
+
+// matchRename reports whether lf's content matches one of the candidate
+// remote keys that are about to be deleted, returning that key if so.
+func (s *Syncer) matchRename(lf localFile, candidates map[string]string) (string, bool) {
+	if len(candidates) == 0 {
+		return "", false
+	}
+	sum, err := md5File(lf.path)
+	if err != nil {
+		return "", false
+	}
+	key, ok := candidates[string(sum)]
+	return key, ok
+}
+
+// Pull downloads every blob that differs from its local counterpart, and
+// (with Options.Delete) removes local files that have no blob counterpart.
+func (s *Syncer) Pull(ctx context.Context) (Stats, error) {
+	locals, err := s.listLocal()
+	if err != nil {
+		return Stats{}, err
+	}
+	localByKey := make(map[string]localFile, len(locals))
+	for _, lf := range locals {
+		localByKey[lf.key] = lf
+	}
+
+	remotes, err := s.listRemote(ctx)
+	if err != nil {
+		return Stats{}, err
+	}
+	remoteKeys := make([]string, 0, len(remotes))
+	for key := range remotes {
+		remoteKeys = append(remoteKeys, key)
+	}
+
+	var stats Stats
+	var mu sync.Mutex
+	err = s.forEach(ctx, len(remoteKeys), func(ctx context.Context, i int) error {
+		key := remoteKeys[i]
+		remote := remotes[key]
+		if lf, ok := localByKey[key]; ok && !s.needsTransfer(lf, remote, false) {
+			s.report(key, "skip", nil)
+			mu.Lock()
+			stats.Skipped++
+			mu.Unlock()
+			return nil
+		}
+		localPath := filepath.Join(s.root, filepath.FromSlash(key))
+		if err := os.MkdirAll(filepath.Dir(localPath), 0o755); err != nil {
+			return fmt.Errorf("sync: creating %q: %w", filepath.Dir(localPath), err)
+		}
+		err := s.backend.GetFile(ctx, key, localPath)
+		s.report(key, "download", err)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		stats.Downloaded++
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return stats, err
+	}
+
+	if s.opts.Delete {
+		for _, lf := range locals {
+			if _, ok := remotes[lf.key]; ok {
+				continue
+			}
+			if err := os.Remove(lf.path); err != nil {
+				return stats, err
+			}
+			s.report(lf.key, "delete", nil)
+			stats.Deleted++
+		}
+	}
+	return stats, nil
+}
+
+// needsTransfer reports whether lf and remote differ under s.opts.Mode.
+// localIsSource says which side Push/Pull treats as the source of truth:
+// true for Push (local -> remote), false for Pull (remote -> local); this
+// only matters for CompareUpdate, which transfers when the source is newer
+// than the destination.
+func (s *Syncer) needsTransfer(lf localFile, remote azureblob.BlobInfo, localIsSource bool) bool {
+	switch s.opts.Mode {
+	case CompareSizeOnly:
+		return lf.size != remote.Size
+	case CompareUpdate:
+		if localIsSource {
+			return lf.modTime.After(remote.LastModified)
+		}
+		return remote.LastModified.After(lf.modTime)
+	default: // CompareChecksum
+		if lf.size != remote.Size {
+			return true
+		}
+		if remote.ContentMD5 == nil {
+			return true
+		}
+		sum, err := md5File(lf.path)
+		if err != nil {
+			return true // err on the side of re-transferring
+		}
+		return !bytes.Equal(sum, remote.ContentMD5)
+	}
+}
+
+func md5File(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	h := md5.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+func (s *Syncer) listLocal() ([]localFile, error) {
+	var files []localFile
+	err := filepath.WalkDir(s.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(s.root, path)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		files = append(files, localFile{
+			key:     filepath.ToSlash(rel),
+			path:    path,
+			size:    info.Size(),
+			modTime: info.ModTime(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("sync: walking %q: %w", s.root, err)
+	}
+	return files, nil
+}
+
+func (s *Syncer) listRemote(ctx context.Context) (map[string]azureblob.BlobInfo, error) {
+	entries, err := s.backend.List(ctx, "", "")
+	if err != nil {
+		return nil, err
+	}
+	remotes := make(map[string]azureblob.BlobInfo, len(entries))
+	for _, e := range entries {
+		if e.IsPrefix {
+			continue
+		}
+		remotes[e.Key] = e
+	}
+	return remotes, nil
+}
+
+func (s *Syncer) report(key, action string, err error) {
+	if s.opts.Progress != nil {
+		s.opts.Progress(Event{Key: key, Action: action, Err: err})
+	}
+}
+
+// forEach runs fn(ctx, i) for i in [0, n) across s.opts.Concurrency workers.
+// The shared ctx is cancelled as soon as any fn call returns an error, which
+// aborts in-flight blob uploads/downloads (including any multipart transfer
+// in progress) instead of letting them run to completion.
+func (s *Syncer) forEach(ctx context.Context, n int, fn func(ctx context.Context, i int) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	sem := make(chan struct{}, s.opts.Concurrency)
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+
+	for i := 0; i < n; i++ {
+		i := i
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if ctx.Err() != nil {
+				return
+			}
+			if err := fn(ctx, i); err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
@@ -0,0 +1,188 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/giuliohome-org/go-azure/pkg/azureblob"
+)
+
+func TestNeedsTransfer(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file.txt")
+	if err := os.WriteFile(path, []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sum, err := md5File(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Now()
+	lf := localFile{key: "file.txt", path: path, size: 5, modTime: modTime}
+
+	tests := []struct {
+		name          string
+		mode          CompareMode
+		remote        azureblob.BlobInfo
+		localIsSource bool
+		want          bool
+	}{
+		{
+			name:          "size-only: same size is up to date",
+			mode:          CompareSizeOnly,
+			remote:        azureblob.BlobInfo{Size: 5},
+			localIsSource: true,
+			want:          false,
+		},
+		{
+			name:          "size-only: different size needs transfer",
+			mode:          CompareSizeOnly,
+			remote:        azureblob.BlobInfo{Size: 3},
+			localIsSource: true,
+			want:          true,
+		},
+		{
+			name:          "push update: local newer than remote needs transfer",
+			mode:          CompareUpdate,
+			remote:        azureblob.BlobInfo{LastModified: modTime.Add(-time.Hour)},
+			localIsSource: true,
+			want:          true,
+		},
+		{
+			name:          "push update: local older than remote is up to date",
+			mode:          CompareUpdate,
+			remote:        azureblob.BlobInfo{LastModified: modTime.Add(time.Hour)},
+			localIsSource: true,
+			want:          false,
+		},
+		{
+			name:          "pull update: remote newer than local needs transfer",
+			mode:          CompareUpdate,
+			remote:        azureblob.BlobInfo{LastModified: modTime.Add(time.Hour)},
+			localIsSource: false,
+			want:          true,
+		},
+		{
+			name:          "pull update: remote older than local is up to date",
+			mode:          CompareUpdate,
+			remote:        azureblob.BlobInfo{LastModified: modTime.Add(-time.Hour)},
+			localIsSource: false,
+			want:          false,
+		},
+		{
+			name:          "checksum: matching size and MD5 is up to date",
+			mode:          CompareChecksum,
+			remote:        azureblob.BlobInfo{Size: 5, ContentMD5: sum},
+			localIsSource: true,
+			want:          false,
+		},
+		{
+			name:          "checksum: different size needs transfer",
+			mode:          CompareChecksum,
+			remote:        azureblob.BlobInfo{Size: 3, ContentMD5: sum},
+			localIsSource: true,
+			want:          true,
+		},
+		{
+			name:          "checksum: missing remote MD5 needs transfer",
+			mode:          CompareChecksum,
+			remote:        azureblob.BlobInfo{Size: 5},
+			localIsSource: true,
+			want:          true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Syncer{opts: Options{Mode: tt.mode}}
+			if got := s.needsTransfer(lf, tt.remote, tt.localIsSource); got != tt.want {
+				t.Errorf("needsTransfer() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenameCandidates(t *testing.T) {
+	remotes := map[string]azureblob.BlobInfo{
+		"kept.txt":    {ContentMD5: []byte("kept")},
+		"deleted.txt": {ContentMD5: []byte("renamed")},
+		"nomd5.txt":   {},
+	}
+	localKeys := map[string]bool{"kept.txt": true}
+
+	got := renameCandidates(remotes, localKeys)
+
+	want := map[string]string{"renamed": "deleted.txt"}
+	if len(got) != len(want) || got["renamed"] != want["renamed"] {
+		t.Fatalf("renameCandidates() = %v, want %v", got, want)
+	}
+}
+
+func TestMatchRename(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "moved.txt")
+	if err := os.WriteFile(path, []byte("content"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sum, err := md5File(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Syncer{}
+
+	t.Run("matching candidate", func(t *testing.T) {
+		key, ok := s.matchRename(localFile{path: path}, map[string]string{string(sum): "old-name.txt"})
+		if !ok || key != "old-name.txt" {
+			t.Fatalf("matchRename() = (%q, %v), want (\"old-name.txt\", true)", key, ok)
+		}
+	})
+
+	t.Run("no candidates", func(t *testing.T) {
+		if _, ok := s.matchRename(localFile{path: path}, nil); ok {
+			t.Fatal("matchRename() with no candidates should not match")
+		}
+	})
+
+	t.Run("no matching checksum", func(t *testing.T) {
+		if _, ok := s.matchRename(localFile{path: path}, map[string]string{"other-sum": "old-name.txt"}); ok {
+			t.Fatal("matchRename() should not match an unrelated checksum")
+		}
+	})
+}
+
+func TestForEachCancelsOnFirstError(t *testing.T) {
+	s := &Syncer{opts: Options{Concurrency: 4}}
+	sentinel := errors.New("boom")
+
+	err := s.forEach(context.Background(), 20, func(ctx context.Context, i int) error {
+		if i == 0 {
+			return sentinel
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	if !errors.Is(err, sentinel) {
+		t.Fatalf("forEach() error = %v, want %v", err, sentinel)
+	}
+}
+
+func TestForEachReturnsNilWithNoErrors(t *testing.T) {
+	s := &Syncer{opts: Options{Concurrency: 4}}
+	var completed int32
+	err := s.forEach(context.Background(), 10, func(ctx context.Context, i int) error {
+		atomic.AddInt32(&completed, 1)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("forEach() error = %v, want nil", err)
+	}
+	if completed != 10 {
+		t.Fatalf("forEach() ran %d calls, want 10", completed)
+	}
+}